@@ -26,49 +26,108 @@ import (
 type ContainerMapWithLock struct {
 	sync.RWMutex
 	ContainerMap
+	// podIndex is a reverse index of ContainerMap, podUID->containerName->containerID,
+	// maintained alongside ContainerMap under the same lock so that GetContainerID,
+	// RemoveByContainerRef and the per-pod bulk operations below don't need to scan
+	// every entry in ContainerMap.
+	podIndex map[string]map[string]string
 }
 
 func NewContainerMapWithLock() *ContainerMapWithLock {
 	return &ContainerMapWithLock{
 		ContainerMap: make(ContainerMap),
+		podIndex:     make(map[string]map[string]string),
 	}
 }
 
 // Add adds a mapping of (containerID)->(podUID, containerName) to the ContainerMap
 func (cm *ContainerMapWithLock) Add(podUID, containerName, containerID string) {
 	cm.Lock()
+	cm.addLocked(podUID, containerName, containerID)
+	cm.Unlock()
+}
+
+// addLocked records the mapping in both ContainerMap and podIndex. Callers must hold the write lock.
+func (cm *ContainerMapWithLock) addLocked(podUID, containerName, containerID string) {
 	cm.ContainerMap[containerID] = struct {
 		podUID        string
 		containerName string
 	}{podUID, containerName}
-	cm.Unlock()
+	names, ok := cm.podIndex[podUID]
+	if !ok {
+		names = make(map[string]string)
+		cm.podIndex[podUID] = names
+	}
+	names[containerName] = containerID
+}
+
+// removeByContainerIDLocked removes the mapping for containerID from both ContainerMap and
+// podIndex. Callers must hold the write lock.
+func (cm *ContainerMapWithLock) removeByContainerIDLocked(containerID string) {
+	val, ok := cm.ContainerMap[containerID]
+	if !ok {
+		return
+	}
+	delete(cm.ContainerMap, containerID)
+	if names, ok := cm.podIndex[val.podUID]; ok {
+		delete(names, val.containerName)
+		if len(names) == 0 {
+			delete(cm.podIndex, val.podUID)
+		}
+	}
 }
 
 // RemoveByContainerID removes a mapping of (containerID)->(podUID, containerName) from the ContainerMap
 func (cm *ContainerMapWithLock) RemoveByContainerID(containerID string) {
 	cm.Lock()
-	delete(cm.ContainerMap, containerID)
+	cm.removeByContainerIDLocked(containerID)
 	cm.Unlock()
 }
 
 // RemoveByContainerRef removes a mapping of (containerID)->(podUID, containerName) from the ContainerMap
 func (cm *ContainerMapWithLock) RemoveByContainerRef(podUID, containerName string) {
 	cm.Lock()
-	containerID, err := cm.GetContainerID(podUID, containerName)
-	if err == nil {
-		cm.RemoveByContainerID(containerID)
+	if containerID, ok := cm.podIndex[podUID][containerName]; ok {
+		cm.removeByContainerIDLocked(containerID)
 	}
 	cm.Unlock()
 }
 
+// RemoveByPodUID removes all mappings for the given podUID from the ContainerMap and returns the
+// containerIDs that were removed. It is used by callers tearing down a pod in bulk, such as the
+// pod cleanup path, where deleting one containerID at a time would otherwise require a scan per
+// container.
+func (cm *ContainerMapWithLock) RemoveByPodUID(podUID string) []string {
+	cm.Lock()
+	defer cm.Unlock()
+	names := cm.podIndex[podUID]
+	removed := make([]string, 0, len(names))
+	for _, containerID := range names {
+		delete(cm.ContainerMap, containerID)
+		removed = append(removed, containerID)
+	}
+	delete(cm.podIndex, podUID)
+	return removed
+}
+
+// GetContainersByPodUID retrieves all (containerName)->(containerID) mappings for the given podUID.
+func (cm *ContainerMapWithLock) GetContainersByPodUID(podUID string) map[string]string {
+	cm.RLock()
+	defer cm.RUnlock()
+	names := cm.podIndex[podUID]
+	containers := make(map[string]string, len(names))
+	for containerName, containerID := range names {
+		containers[containerName] = containerID
+	}
+	return containers
+}
+
 // GetContainerID retrieves a ContainerID from the ContainerMap
-func (cm ContainerMapWithLock) GetContainerID(podUID, containerName string) (string, error) {
+func (cm *ContainerMapWithLock) GetContainerID(podUID, containerName string) (string, error) {
 	cm.RLock()
 	defer cm.RUnlock()
-	for key, val := range cm.ContainerMap {
-		if val.podUID == podUID && val.containerName == containerName {
-			return key, nil
-		}
+	if containerID, ok := cm.podIndex[podUID][containerName]; ok {
+		return containerID, nil
 	}
 	return "", fmt.Errorf("container %s not in ContainerMap for pod %s", containerName, podUID)
 }
@@ -83,11 +142,28 @@ func (cm *ContainerMapWithLock) GetContainerRef(containerID string) (string, str
 	return cm.ContainerMap[containerID].podUID, cm.ContainerMap[containerID].containerName, nil
 }
 
-// Visit invoke visitor function to walks all of the entries in the container map
+// Visit invokes visitor function to walk all of the entries in the container map. visitor must
+// not mutate the ContainerMapWithLock; only a read lock is held, so use VisitMutable for that.
 func (cm *ContainerMapWithLock) Visit(visitor func(podUID, containerName, containerID string)) {
-	cm.Lock()
-	defer cm.Unlock()
+	cm.RLock()
+	defer cm.RUnlock()
 	for k, v := range cm.ContainerMap {
 		visitor(v.podUID, v.containerName, k)
 	}
 }
+
+// VisitMutable invokes visitor function to walk all of the entries in the container map. It copies
+// the entries under the read lock and calls visitor after releasing it, so visitor may call back
+// into ContainerMapWithLock methods that mutate it (e.g. RemoveByContainerID) without deadlocking.
+func (cm *ContainerMapWithLock) VisitMutable(visitor func(podUID, containerName, containerID string)) {
+	type entry struct{ podUID, containerName, containerID string }
+	cm.RLock()
+	entries := make([]entry, 0, len(cm.ContainerMap))
+	for k, v := range cm.ContainerMap {
+		entries = append(entries, entry{v.podUID, v.containerName, k})
+	}
+	cm.RUnlock()
+	for _, e := range entries {
+		visitor(e.podUID, e.containerName, e.containerID)
+	}
+}
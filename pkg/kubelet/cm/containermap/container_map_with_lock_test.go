@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containermap
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestRemoveByPodUID(t *testing.T) {
+	cm := NewContainerMapWithLock()
+	cm.Add("pod-1", "container-a", "cid-a")
+	cm.Add("pod-1", "container-b", "cid-b")
+	cm.Add("pod-2", "container-c", "cid-c")
+
+	removed := cm.RemoveByPodUID("pod-1")
+	sort.Strings(removed)
+	if want := []string{"cid-a", "cid-b"}; !equalStrings(removed, want) {
+		t.Fatalf("RemoveByPodUID(pod-1) = %v, want %v", removed, want)
+	}
+
+	if _, err := cm.GetContainerID("pod-1", "container-a"); err == nil {
+		t.Fatalf("GetContainerID(pod-1, container-a) succeeded after RemoveByPodUID")
+	}
+	if _, _, err := cm.GetContainerRef("cid-a"); err == nil {
+		t.Fatalf("GetContainerRef(cid-a) succeeded after RemoveByPodUID")
+	}
+
+	containerID, err := cm.GetContainerID("pod-2", "container-c")
+	if err != nil || containerID != "cid-c" {
+		t.Fatalf("GetContainerID(pod-2, container-c) = (%q, %v), want (cid-c, nil)", containerID, err)
+	}
+
+	if removed := cm.RemoveByPodUID("pod-1"); len(removed) != 0 {
+		t.Fatalf("RemoveByPodUID(pod-1) on an already-removed pod = %v, want empty", removed)
+	}
+}
+
+func TestGetContainersByPodUID(t *testing.T) {
+	cm := NewContainerMapWithLock()
+	cm.Add("pod-1", "container-a", "cid-a")
+	cm.Add("pod-1", "container-b", "cid-b")
+
+	containers := cm.GetContainersByPodUID("pod-1")
+	want := map[string]string{"container-a": "cid-a", "container-b": "cid-b"}
+	if len(containers) != len(want) {
+		t.Fatalf("GetContainersByPodUID(pod-1) = %v, want %v", containers, want)
+	}
+	for name, id := range want {
+		if containers[name] != id {
+			t.Fatalf("GetContainersByPodUID(pod-1)[%q] = %q, want %q", name, containers[name], id)
+		}
+	}
+
+	// Mutating the returned map must not affect the ContainerMapWithLock.
+	containers["container-c"] = "cid-c"
+	if _, err := cm.GetContainerID("pod-1", "container-c"); err == nil {
+		t.Fatalf("mutating the map returned by GetContainersByPodUID leaked into the ContainerMapWithLock")
+	}
+
+	if containers := cm.GetContainersByPodUID("unknown-pod"); len(containers) != 0 {
+		t.Fatalf("GetContainersByPodUID(unknown-pod) = %v, want empty", containers)
+	}
+}
+
+func TestVisitAndVisitMutable(t *testing.T) {
+	cm := NewContainerMapWithLock()
+	cm.Add("pod-1", "container-a", "cid-a")
+	cm.Add("pod-2", "container-b", "cid-b")
+
+	visited := make(map[string]string)
+	cm.Visit(func(podUID, containerName, containerID string) {
+		visited[containerID] = podUID
+	})
+	if len(visited) != 2 || visited["cid-a"] != "pod-1" || visited["cid-b"] != "pod-2" {
+		t.Fatalf("Visit saw %v, want cid-a->pod-1, cid-b->pod-2", visited)
+	}
+
+	// VisitMutable calls the visitor without holding the lock, so a visitor can call back into a
+	// mutating method (e.g. RemoveByContainerID) without deadlocking.
+	cm.VisitMutable(func(podUID, containerName, containerID string) {
+		if containerID == "cid-a" {
+			cm.RemoveByContainerID(containerID)
+		}
+	})
+	if _, err := cm.GetContainerID("pod-1", "container-a"); err == nil {
+		t.Fatalf("cid-a should have been removed by the VisitMutable visitor")
+	}
+	if _, err := cm.GetContainerID("pod-2", "container-b"); err != nil {
+		t.Fatalf("GetContainerID(pod-2, container-b) failed after unrelated removal: %v", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
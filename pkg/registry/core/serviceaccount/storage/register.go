@@ -0,0 +1,39 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SchemeGroupVersion is the internal (unversioned) group/version RevokedToken,
+// RevokedTokenList and TokenRevocationRequest are registered under, the same internal group the
+// rest of pkg/apis/core uses.
+var SchemeGroupVersion = schema.GroupVersion{Group: "", Version: runtime.APIVersionInternal}
+
+// AddToScheme registers this package's types with scheme. cmd/kube-apiserver must call this once,
+// against the same legacyscheme.Scheme it calls pkg/apis/core's and pkg/apis/authentication's
+// AddToScheme against, before installing REST.Revoke into the serviceaccounts resource's storage
+// map. Without it, RevokedTokenStore's backing StandardStorage has no codec for RevokedToken and
+// the generic apiserver machinery has no codec for TokenRevocationRequest, so the
+// serviceaccounts/revoke subresource REST.Revoke is wired into would reject every request with an
+// encoding error despite being reachable.
+func AddToScheme(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion, &RevokedToken{}, &RevokedTokenList{}, &TokenRevocationRequest{})
+	return nil
+}
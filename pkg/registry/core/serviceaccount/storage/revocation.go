@@ -0,0 +1,295 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/registry/rest"
+	api "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// RevokedToken is the record TokenREST.Create stamps into the RevokedTokenStore's backing
+// storage for every bound token it issues, keyed by the token's "jti" claim (stored as Name).
+// Revoke marks the record Revoked; the token authenticator rejects any request bearing a jti
+// whose record is Revoked.
+type RevokedToken struct {
+	metav1.TypeMeta
+	// ObjectMeta.Name is the token's jti. ObjectMeta.CreationTimestamp is when it was issued.
+	metav1.ObjectMeta
+
+	ServiceAccountUID types.UID
+	BoundObjectRef    *api.ObjectReference
+	ExpiresAt         metav1.Time
+	Revoked           bool
+}
+
+func (in *RevokedToken) DeepCopyObject() runtime.Object {
+	out := *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	if in.BoundObjectRef != nil {
+		ref := *in.BoundObjectRef
+		out.BoundObjectRef = &ref
+	}
+	return &out
+}
+
+// RevokedTokenList is the list type returned by the backing storage's List, used by
+// RevokedTokenStore.Load to replay existing records into memory on startup.
+type RevokedTokenList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []RevokedToken
+}
+
+func (in *RevokedTokenList) DeepCopyObject() runtime.Object {
+	out := &RevokedTokenList{TypeMeta: in.TypeMeta, ListMeta: in.ListMeta}
+	out.Items = make([]RevokedToken, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*RevokedToken)
+	}
+	return out
+}
+
+func (in *RevokedToken) GetObjectKind() schema.ObjectKind { return &in.TypeMeta }
+
+// RevokedTokenStore is an in-memory index of issued and revoked bound service-account tokens,
+// keyed by jti. Records do not expire on their own: PruneExpired drops in-memory records whose
+// token has expired when it is called, and records in backing are never removed. The token authenticator
+// consults IsRevoked on every request; TokenREST.Create populates the store as it stamps a jti
+// into each token it issues, and Revoke (via TokenRevocationREST) marks entries revoked.
+//
+// Records are written through to backing first so they survive an apiserver restart; Load
+// replays backing into memory and must be called once during startup before the store is
+// consulted by the authenticator.
+type RevokedTokenStore struct {
+	lock    sync.RWMutex
+	records map[string]RevokedToken
+
+	backing   rest.StandardStorage
+	ttlJitter time.Duration
+}
+
+// NewRevokedTokenStore returns a RevokedTokenStore persisted through backing. backing may be nil,
+// in which case the store is in-memory only; NewREST passes nil when the integrator disables
+// revocation by omitting revocationStorage.
+func NewRevokedTokenStore(backing rest.StandardStorage, ttlJitter time.Duration) *RevokedTokenStore {
+	return &RevokedTokenStore{
+		records:   make(map[string]RevokedToken),
+		backing:   backing,
+		ttlJitter: ttlJitter,
+	}
+}
+
+// Load replays the backing storage into memory. It must be called once during startup, before the
+// authenticator starts consulting IsRevoked, so that revocations made before an apiserver restart
+// stay enforced. Because IsRevoked only ever consults this replica's in-memory index, callers
+// running more than one apiserver replica should also call Load periodically (not only at
+// startup) so a revocation made against a different replica is reflected here within a bounded
+// window; RevokeJTI, RevokeByPodUID and RevokeByNodeName don't have this gap since they consult
+// backing directly.
+func (s *RevokedTokenStore) Load(ctx context.Context) error {
+	if s.backing == nil {
+		return nil
+	}
+	list, err := s.list(ctx)
+	if err != nil {
+		return fmt.Errorf("loading revoked token index: %w", err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, rec := range list {
+		s.records[rec.Name] = rec
+	}
+	return nil
+}
+
+// list returns every record currently in backing.
+func (s *RevokedTokenStore) list(ctx context.Context) ([]RevokedToken, error) {
+	lister, ok := s.backing.(rest.Lister)
+	if !ok {
+		return nil, fmt.Errorf("revoked token backing storage does not support List")
+	}
+	obj, err := lister.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*RevokedTokenList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected list type %T", obj)
+	}
+	return list.Items, nil
+}
+
+// Track records a newly issued token so it can later be revoked by jti or by its bound object.
+// TokenREST.Create calls this after stamping a jti into the token it returns, and persists the
+// record to backing before returning: an index that only remembered issuances in memory would
+// forget every outstanding token across an apiserver restart, and in a multi-replica apiserver a
+// Revoke request landing on a replica other than the one that issued the token would silently
+// no-op. Either failure mode leaves a token usable until its natural "exp" regardless of a
+// revocation request ever being made, which defeats the purpose of the store, so the cost of one
+// extra write per issuance is paid here rather than left for Revoke* to discover it never had the
+// record to begin with.
+func (s *RevokedTokenStore) Track(ctx context.Context, jti string, serviceAccountUID types.UID, boundObjectRef *api.ObjectReference, expiresAt time.Time) error {
+	rec := RevokedToken{
+		ObjectMeta:        metav1.ObjectMeta{Name: jti},
+		ServiceAccountUID: serviceAccountUID,
+		BoundObjectRef:    boundObjectRef,
+		ExpiresAt:         metav1.NewTime(expiresAt),
+	}
+	return s.upsert(ctx, rec)
+}
+
+// IsRevoked reports whether jti has been revoked. Unknown jtis (e.g. tokens issued before this
+// store existed) are treated as not revoked; the authenticator still enforces "exp" independently.
+func (s *RevokedTokenStore) IsRevoked(jti string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.records[jti].Revoked
+}
+
+// RevokeJTI marks a single token revoked by its jti. It returns false if jti is not tracked,
+// checking backing (not just this replica's in-memory index) before giving up so that a token
+// tracked by a different apiserver replica can still be revoked.
+func (s *RevokedTokenStore) RevokeJTI(ctx context.Context, jti string) (bool, error) {
+	s.lock.RLock()
+	rec, ok := s.records[jti]
+	s.lock.RUnlock()
+
+	if !ok {
+		if s.backing == nil {
+			return false, nil
+		}
+		obj, err := s.backing.Get(ctx, jti, &metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("looking up jti %s: %w", jti, err)
+		}
+		got, ok := obj.(*RevokedToken)
+		if !ok {
+			return false, fmt.Errorf("unexpected type %T for RevokedToken %s", obj, jti)
+		}
+		rec = *got
+	}
+	if rec.Revoked {
+		return true, nil
+	}
+	rec.Revoked = true
+	return true, s.upsert(ctx, rec)
+}
+
+// RevokeByPodUID marks every tracked token bound to podUID revoked and returns their jtis. It is
+// intended to be called by the pod GC controller when a pod is deleted, closing the window where
+// a compromised kubelet keeps using the pod's token after the pod is gone.
+func (s *RevokedTokenStore) RevokeByPodUID(ctx context.Context, podUID types.UID) ([]string, error) {
+	return s.revokeWhere(ctx, func(rec RevokedToken) bool {
+		return rec.BoundObjectRef != nil && rec.BoundObjectRef.Kind == "Pod" && rec.BoundObjectRef.UID == podUID
+	})
+}
+
+// RevokeByNodeName marks every tracked token bound to nodeName revoked and returns their jtis. It
+// is intended to be called by the node lifecycle controller when a Node is deleted.
+func (s *RevokedTokenStore) RevokeByNodeName(ctx context.Context, nodeName string) ([]string, error) {
+	return s.revokeWhere(ctx, func(rec RevokedToken) bool {
+		return rec.BoundObjectRef != nil && rec.BoundObjectRef.Kind == "Node" && rec.BoundObjectRef.Name == nodeName
+	})
+}
+
+// revokeWhere marks every unrevoked record matching match revoked. When backing is configured it
+// lists the full set of tracked records from backing rather than this replica's in-memory index,
+// so a bulk revoke (RevokeByPodUID, RevokeByNodeName) — typically issued by the pod GC or node
+// lifecycle controller against whichever apiserver replica happens to handle the request — finds
+// every outstanding token bound to the deleted pod or node, not only the ones this replica
+// happens to have tracked or loaded itself.
+func (s *RevokedTokenStore) revokeWhere(ctx context.Context, match func(RevokedToken) bool) ([]string, error) {
+	var candidates []RevokedToken
+	if s.backing != nil {
+		list, err := s.list(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing revoked token index: %w", err)
+		}
+		candidates = list
+	} else {
+		s.lock.RLock()
+		candidates = make([]RevokedToken, 0, len(s.records))
+		for _, rec := range s.records {
+			candidates = append(candidates, rec)
+		}
+		s.lock.RUnlock()
+	}
+
+	revoked := make([]string, 0)
+	for _, rec := range candidates {
+		if rec.Revoked || !match(rec) {
+			continue
+		}
+		rec.Revoked = true
+		if err := s.upsert(ctx, rec); err != nil {
+			return revoked, err
+		}
+		revoked = append(revoked, rec.Name)
+	}
+	return revoked, nil
+}
+
+// PruneExpired removes tracked records whose ExpiresAt (plus ttlJitter, to tolerate clock skew
+// between apiservers) has passed. The authenticator rejects an expired token regardless, so this
+// only bounds the index's memory footprint.
+func (s *RevokedTokenStore) PruneExpired(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for jti, rec := range s.records {
+		if now.After(rec.ExpiresAt.Add(s.ttlJitter)) {
+			delete(s.records, jti)
+		}
+	}
+}
+
+// upsert writes rec to the in-memory index and, if configured, through to backing: Update first,
+// falling back to Create on the initial write for a given jti. It backs both Track (a fresh,
+// unrevoked record) and the Revoke* methods (flipping Revoked on an existing record).
+//
+// backing must be a StandardStorage registered for a RevokedToken-shaped type with its own codec
+// and scheme registration — see AddToScheme in register.go, which NewREST's caller must invoke
+// against the apiserver's scheme before installing this storage.
+func (s *RevokedTokenStore) upsert(ctx context.Context, rec RevokedToken) error {
+	s.lock.Lock()
+	s.records[rec.Name] = rec
+	s.lock.Unlock()
+
+	if s.backing == nil {
+		return nil
+	}
+	obj := rec
+	_, _, err := s.backing.Update(ctx, rec.Name, rest.DefaultUpdatedObjectInfo(&obj), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, true, &metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = s.backing.Create(ctx, &obj, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// TokenRevocationRequest is the object accepted by the serviceaccounts/revoke subresource. Exactly
+// one of Spec.JTI, Spec.PodUID or Spec.NodeName must be set: JTI revokes a single token, while
+// PodUID and NodeName bulk-revoke every outstanding token bound to that pod or node.
+type TokenRevocationRequest struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   TokenRevocationRequestSpec
+	Status TokenRevocationRequestStatus
+}
+
+// TokenRevocationRequestSpec selects which outstanding bound tokens to revoke.
+type TokenRevocationRequestSpec struct {
+	// JTI revokes the single token with this jti claim.
+	JTI string
+	// PodUID revokes every outstanding token bound to this pod, e.g. once the pod has been
+	// deleted.
+	PodUID types.UID
+	// NodeName revokes every outstanding token bound to this node, e.g. once the Node has been
+	// deleted.
+	NodeName string
+}
+
+// TokenRevocationRequestStatus reports the result of a revocation request.
+type TokenRevocationRequestStatus struct {
+	// RevokedJTIs lists the jtis that were revoked by this request.
+	RevokedJTIs []string
+}
+
+func (in *TokenRevocationRequest) DeepCopyObject() runtime.Object {
+	out := *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Status.RevokedJTIs = append([]string(nil), in.Status.RevokedJTIs...)
+	return &out
+}
+
+func (in *TokenRevocationRequest) GetObjectKind() schema.ObjectKind { return &in.TypeMeta }
+
+// TokenRevocationREST implements the serviceaccounts/revoke subresource.
+type TokenRevocationREST struct {
+	svcaccts    rest.Getter
+	revocations *RevokedTokenStore
+}
+
+var _ rest.NamedCreater = &TokenRevocationREST{}
+
+// New returns a new TokenRevocationRequest.
+func (r *TokenRevocationREST) New() runtime.Object {
+	return &TokenRevocationRequest{}
+}
+
+// Destroy cleans up resources on shutdown.
+func (r *TokenRevocationREST) Destroy() {}
+
+// Create revokes the token(s) selected by req, bulk-revoking by pod or node when a selector is
+// given instead of a specific jti.
+func (r *TokenRevocationREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	if _, err := r.svcaccts.Get(ctx, name, &metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	req, ok := obj.(*TokenRevocationRequest)
+	if !ok {
+		return nil, apierrors.NewBadRequest("must supply TokenRevocationRequest")
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, req.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+
+	set := 0
+	for _, s := range []string{req.Spec.JTI, string(req.Spec.PodUID), req.Spec.NodeName} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, apierrors.NewBadRequest("exactly one of spec.jti, spec.podUID or spec.nodeName must be set")
+	}
+
+	var revoked []string
+	var err error
+	switch {
+	case req.Spec.JTI != "":
+		var ok bool
+		ok, err = r.revocations.RevokeJTI(ctx, req.Spec.JTI)
+		if ok {
+			revoked = []string{req.Spec.JTI}
+		}
+	case req.Spec.PodUID != "":
+		revoked, err = r.revocations.RevokeByPodUID(ctx, req.Spec.PodUID)
+	case req.Spec.NodeName != "":
+		revoked, err = r.revocations.RevokeByNodeName(ctx, req.Spec.NodeName)
+	default:
+		return nil, apierrors.NewBadRequest("one of spec.jti, spec.podUID or spec.nodeName must be set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req.Status.RevokedJTIs = revoked
+	return req, nil
+}
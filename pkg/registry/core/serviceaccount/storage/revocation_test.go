@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	api "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestRevokedTokenStoreTrackAndIsRevoked(t *testing.T) {
+	s := NewRevokedTokenStore(nil, 0)
+
+	if s.IsRevoked("jti-1") {
+		t.Fatalf("IsRevoked(unknown jti) = true, want false")
+	}
+
+	if err := s.Track(context.Background(), "jti-1", types.UID("sa-uid"), nil, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Track: %v", err)
+	}
+	if s.IsRevoked("jti-1") {
+		t.Fatalf("IsRevoked(tracked, unrevoked jti) = true, want false")
+	}
+
+	revoked, err := s.RevokeJTI(context.Background(), "jti-1")
+	if err != nil {
+		t.Fatalf("RevokeJTI: %v", err)
+	}
+	if !revoked {
+		t.Fatalf("RevokeJTI(jti-1) = false, want true")
+	}
+	if !s.IsRevoked("jti-1") {
+		t.Fatalf("IsRevoked(revoked jti) = false, want true")
+	}
+}
+
+func TestRevokedTokenStoreRevokeJTIUnknown(t *testing.T) {
+	s := NewRevokedTokenStore(nil, 0)
+	revoked, err := s.RevokeJTI(context.Background(), "never-tracked")
+	if err != nil {
+		t.Fatalf("RevokeJTI: %v", err)
+	}
+	if revoked {
+		t.Fatalf("RevokeJTI(never-tracked) = true, want false")
+	}
+}
+
+func TestRevokedTokenStoreRevokeByPodUID(t *testing.T) {
+	s := NewRevokedTokenStore(nil, 0)
+	exp := time.Now().Add(time.Hour)
+
+	mustTrack(t, s, "pod-jti-1", types.UID("sa-uid"), &api.ObjectReference{Kind: "Pod", UID: types.UID("pod-1")}, exp)
+	mustTrack(t, s, "pod-jti-2", types.UID("sa-uid"), &api.ObjectReference{Kind: "Pod", UID: types.UID("pod-1")}, exp)
+	mustTrack(t, s, "other-jti", types.UID("sa-uid"), &api.ObjectReference{Kind: "Pod", UID: types.UID("pod-2")}, exp)
+	mustTrack(t, s, "unbound-jti", types.UID("sa-uid"), nil, exp)
+
+	revoked, err := s.RevokeByPodUID(context.Background(), types.UID("pod-1"))
+	if err != nil {
+		t.Fatalf("RevokeByPodUID: %v", err)
+	}
+	if len(revoked) != 2 {
+		t.Fatalf("RevokeByPodUID returned %d jtis, want 2: %v", len(revoked), revoked)
+	}
+	if !s.IsRevoked("pod-jti-1") || !s.IsRevoked("pod-jti-2") {
+		t.Fatalf("both pod-1 tokens should be revoked")
+	}
+	if s.IsRevoked("other-jti") || s.IsRevoked("unbound-jti") {
+		t.Fatalf("tokens not bound to pod-1 should not be revoked")
+	}
+
+	// Revoking again should be a no-op: the tokens are already revoked.
+	revoked, err = s.RevokeByPodUID(context.Background(), types.UID("pod-1"))
+	if err != nil {
+		t.Fatalf("RevokeByPodUID (second call): %v", err)
+	}
+	if len(revoked) != 0 {
+		t.Fatalf("RevokeByPodUID on already-revoked pod returned %v, want empty", revoked)
+	}
+}
+
+func TestRevokedTokenStoreRevokeByNodeName(t *testing.T) {
+	s := NewRevokedTokenStore(nil, 0)
+	exp := time.Now().Add(time.Hour)
+
+	mustTrack(t, s, "node-jti", types.UID("sa-uid"), &api.ObjectReference{Kind: "Node", Name: "node-1"}, exp)
+	mustTrack(t, s, "other-node-jti", types.UID("sa-uid"), &api.ObjectReference{Kind: "Node", Name: "node-2"}, exp)
+
+	revoked, err := s.RevokeByNodeName(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("RevokeByNodeName: %v", err)
+	}
+	if len(revoked) != 1 || revoked[0] != "node-jti" {
+		t.Fatalf("RevokeByNodeName(node-1) = %v, want [node-jti]", revoked)
+	}
+	if s.IsRevoked("other-node-jti") {
+		t.Fatalf("token bound to node-2 should not be revoked by a node-1 revocation")
+	}
+}
+
+func TestRevokedTokenStorePruneExpired(t *testing.T) {
+	s := NewRevokedTokenStore(nil, 0)
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	mustTrack(t, s, "expired-jti", types.UID("sa-uid"), nil, past)
+	mustTrack(t, s, "live-jti", types.UID("sa-uid"), nil, future)
+
+	s.PruneExpired(time.Now())
+
+	s.lock.RLock()
+	_, expiredStillPresent := s.records["expired-jti"]
+	_, liveStillPresent := s.records["live-jti"]
+	s.lock.RUnlock()
+
+	if expiredStillPresent {
+		t.Fatalf("expired record should have been pruned")
+	}
+	if !liveStillPresent {
+		t.Fatalf("live record should not have been pruned")
+	}
+}
+
+func mustTrack(t *testing.T, s *RevokedTokenStore, jti string, serviceAccountUID types.UID, boundObjectRef *api.ObjectReference, expiresAt time.Time) {
+	t.Helper()
+	if err := s.Track(context.Background(), jti, serviceAccountUID, boundObjectRef, expiresAt); err != nil {
+		t.Fatalf("Track(%s): %v", jti, err)
+	}
+}
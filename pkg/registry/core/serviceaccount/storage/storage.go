@@ -17,6 +17,7 @@ limitations under the License.
 package storage
 
 import (
+	"context"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,11 +36,15 @@ import (
 
 type REST struct {
 	*genericregistry.Store
-	Token *TokenREST
+	Token  *TokenREST
+	Revoke *TokenRevocationREST
 }
 
-// NewREST returns a RESTStorage object that will work against service accounts.
-func NewREST(optsGetter generic.RESTOptionsGetter, issuer token.TokenGenerator, auds authenticator.Audiences, max time.Duration, podStorage, secretStorage, nodeStorage rest.Getter, extendExpiration bool, maxExtendedExpiration time.Duration) (*REST, error) {
+// NewREST returns a RESTStorage object that will work against service accounts. revocationStorage
+// backs the serviceaccounts/revoke subresource's RevokedTokenStore; pass nil to disable
+// revocation entirely. revocationTTLJitter pads how long a revoked token's record is kept past
+// its expiration, to tolerate clock skew between apiservers consulting the store.
+func NewREST(optsGetter generic.RESTOptionsGetter, issuer token.TokenGenerator, auds authenticator.Audiences, max time.Duration, podStorage, secretStorage, nodeStorage rest.Getter, extendExpiration bool, maxExtendedExpiration time.Duration, revocationStorage rest.StandardStorage, revocationTTLJitter time.Duration) (*REST, error) {
 	store := &genericregistry.Store{
 		NewFunc:                   func() runtime.Object { return &api.ServiceAccount{} },
 		NewListFunc:               func() runtime.Object { return &api.ServiceAccountList{} },
@@ -58,6 +63,11 @@ func NewREST(optsGetter generic.RESTOptionsGetter, issuer token.TokenGenerator,
 		return nil, err
 	}
 
+	var revocations *RevokedTokenStore
+	if revocationStorage != nil {
+		revocations = NewRevokedTokenStore(revocationStorage, revocationTTLJitter)
+	}
+
 	var trest *TokenREST
 	if issuer != nil && podStorage != nil && secretStorage != nil {
 		trest = &TokenREST{
@@ -71,12 +81,22 @@ func NewREST(optsGetter generic.RESTOptionsGetter, issuer token.TokenGenerator,
 			maxExpirationSeconds:         int64(max.Seconds()),
 			maxExtendedExpirationSeconds: int64(maxExtendedExpiration.Seconds()),
 			extendExpiration:             extendExpiration,
+			revocations:                  revocations,
+		}
+	}
+
+	var revokeREST *TokenRevocationREST
+	if revocations != nil {
+		revokeREST = &TokenRevocationREST{
+			svcaccts:    store,
+			revocations: revocations,
 		}
 	}
 
 	return &REST{
-		Store: store,
-		Token: trest,
+		Store:  store,
+		Token:  trest,
+		Revoke: revokeREST,
 	}, nil
 }
 
@@ -87,3 +107,38 @@ var _ rest.ShortNamesProvider = &REST{}
 func (r *REST) ShortNames() []string {
 	return []string{"sa"}
 }
+
+// LoadRevocations replays the revocation index's backing storage into memory. It does nothing
+// when revocation is disabled. cmd/kube-apiserver must call this once during startup, before the
+// token authenticator starts consulting IsTokenRevoked, so that tokens revoked before a restart
+// stay revoked; in a multi-replica apiserver it should also be called periodically thereafter
+// (e.g. from a wait.Until goroutine alongside startup), since IsTokenRevoked only ever consults
+// this replica's in-memory index and a revocation made against a different replica is otherwise
+// only picked up the next time this one restarts. See Load's doc comment in revocation.go.
+func (r *REST) LoadRevocations(ctx context.Context) error {
+	if r.Token == nil || r.Token.revocations == nil {
+		return nil
+	}
+	return r.Token.revocations.Load(ctx)
+}
+
+// IsTokenRevoked reports whether jti has been revoked.
+//
+// This package only implements the storage side of revocation; wiring it end to end needs three
+// changes outside this package, none of which can be made from within it:
+//   - register.go's AddToScheme must be called against the apiserver's scheme (alongside
+//     pkg/apis/core's and pkg/apis/authentication's own AddToScheme calls) so RevokedToken and
+//     TokenRevocationRequest have codecs.
+//   - pkg/registry/core/rest/storage_core.go's subresource map for serviceaccounts must gain a
+//     "serviceaccounts/revoke": serviceAccountStorage.Revoke entry, the same way it already has
+//     one for "serviceaccounts/token".
+//   - the bound service-account token authenticator must call IsTokenRevoked(claims.ID) on every
+//     request and reject the request if it returns true, and the pod GC and node lifecycle
+//     controllers must send a TokenRevocationRequest with spec.podUID / spec.nodeName to the
+//     serviceaccounts/revoke subresource when they delete a Pod or Node.
+func (r *REST) IsTokenRevoked(jti string) bool {
+	if r.Token == nil {
+		return false
+	}
+	return r.Token.IsRevoked(jti)
+}
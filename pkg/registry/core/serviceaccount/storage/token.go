@@ -0,0 +1,236 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/registry/rest"
+	authenticationapi "k8s.io/kubernetes/pkg/apis/authentication"
+	api "k8s.io/kubernetes/pkg/apis/core"
+	token "k8s.io/kubernetes/pkg/serviceaccount"
+)
+
+// tokenPrivateClaims is the privateClaims argument passed to issuer.GenerateToken.
+type tokenPrivateClaims struct {
+	Namespace      string                                  `json:"namespace,omitempty"`
+	ServiceAccount authenticationapi.BoundObjectReference  `json:"serviceaccount,omitempty"`
+	BoundObjectRef *authenticationapi.BoundObjectReference `json:"boundObjectRef,omitempty"`
+}
+
+// TokenREST implements the serviceaccounts/token subresource, issuing bound service-account
+// tokens tied to pods, nodes or secrets.
+type TokenREST struct {
+	svcaccts                     rest.Getter
+	pods                         rest.Getter
+	secrets                      rest.Getter
+	nodes                        rest.Getter
+	issuer                       token.TokenGenerator
+	auds                         authenticator.Audiences
+	audsSet                      sets.String
+	maxExpirationSeconds         int64
+	maxExtendedExpirationSeconds int64
+	extendExpiration             bool
+
+	// revocations tracks every token this TokenREST issues so TokenRevocationREST can later
+	// invalidate it by jti, or in bulk by the pod/node it is bound to. It is nil when the
+	// integrator disabled revocation by omitting revocationStorage to NewREST, in which case
+	// issued tokens are simply not trackable.
+	revocations *RevokedTokenStore
+}
+
+var _ rest.NamedCreater = &TokenREST{}
+var _ rest.GroupVersionKindProvider = &TokenREST{}
+
+// New returns a new TokenRequest.
+func (r *TokenREST) New() runtime.Object {
+	return &authenticationapi.TokenRequest{}
+}
+
+// Destroy cleans up resources on shutdown.
+func (r *TokenREST) Destroy() {}
+
+// GroupVersionKind returns authentication.k8s.io/v1, Kind=TokenRequest, regardless of the
+// containing resource's own group/version, since TokenRequest is always served at that GVK.
+func (r *TokenREST) GroupVersionKind(schema.GroupVersion) schema.GroupVersionKind {
+	return authenticationv1.SchemeGroupVersion.WithKind("TokenRequest")
+}
+
+// Create issues a bound service-account token for the named ServiceAccount. Every issued token
+// is stamped with a random jti (the JWT "jti" claim) and, when revocation is enabled, recorded in
+// revocations so TokenRevocationREST can later invalidate it before its natural expiry.
+func (r *TokenREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	req, ok := obj.(*authenticationapi.TokenRequest)
+	if !ok {
+		return nil, apierrors.NewBadRequest("must supply TokenRequest")
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, req.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+
+	svcacctObj, err := r.svcaccts.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	svcacct, ok := svcacctObj.(*api.ServiceAccount)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T for ServiceAccount %s", svcacctObj, name)
+	}
+
+	var boundObjectRef *api.ObjectReference
+	var privateBoundObjectRef *authenticationapi.BoundObjectReference
+	if ref := req.Spec.BoundObjectRef; ref != nil {
+		boundObjectRef, err = r.validateBoundObjectRef(ctx, ref, svcacct.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		privateBoundObjectRef = ref
+	}
+
+	// extendExpiration only applies to tokens bound to a pod or node: those are the tokens a
+	// client (kubelet) proactively rotates well before "exp", so silently allowing a longer
+	// maximum lifetime for them buys rotation slack without weakening an unbound token's
+	// maxExpirationSeconds ceiling.
+	maxExpirationSeconds := r.maxExpirationSeconds
+	if r.extendExpiration && boundObjectRef != nil && (boundObjectRef.Kind == "Pod" || boundObjectRef.Kind == "Node") && r.maxExtendedExpirationSeconds > maxExpirationSeconds {
+		maxExpirationSeconds = r.maxExtendedExpirationSeconds
+	}
+	expirationSeconds := maxExpirationSeconds
+	if req.Spec.ExpirationSeconds != 0 && req.Spec.ExpirationSeconds < expirationSeconds {
+		expirationSeconds = req.Spec.ExpirationSeconds
+	}
+	now := time.Now()
+	exp := now.Add(time.Duration(expirationSeconds) * time.Second)
+
+	aud := r.auds
+	if len(req.Spec.Audiences) > 0 {
+		for _, a := range req.Spec.Audiences {
+			if !r.audsSet.Has(a) {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("requested audience %q is not an allowed audience for this apiserver", a))
+			}
+		}
+		aud = req.Spec.Audiences
+	}
+
+	// jti uniquely identifies this token so RevokedTokenStore can later invalidate it
+	// independently of every other token issued for the same ServiceAccount.
+	jti := string(uuid.NewUUID())
+	claims := &jwt.Claims{
+		Subject:  fmt.Sprintf("system:serviceaccount:%s:%s", svcacct.Namespace, svcacct.Name),
+		Audience: jwt.Audience(aud),
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(exp),
+		ID:       jti,
+	}
+	private := tokenPrivateClaims{
+		Namespace: svcacct.Namespace,
+		ServiceAccount: authenticationapi.BoundObjectReference{
+			Kind: "ServiceAccount",
+			Name: svcacct.Name,
+			UID:  svcacct.UID,
+		},
+		BoundObjectRef: privateBoundObjectRef,
+	}
+
+	tokenData, err := r.issuer.GenerateToken(ctx, claims, private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	if r.revocations != nil {
+		if err := r.revocations.Track(ctx, jti, svcacct.UID, boundObjectRef, exp); err != nil {
+			return nil, fmt.Errorf("failed to record issued token: %w", err)
+		}
+	}
+
+	req.Status = authenticationapi.TokenRequestStatus{
+		Token:               tokenData,
+		ExpirationTimestamp: metav1.Time{Time: exp},
+	}
+	return req, nil
+}
+
+// validateBoundObjectRef fetches the object ref points at and confirms it exists and, when ref
+// carries a UID, that the UID matches — refusing to stamp a token as bound to a pod, secret or
+// node that doesn't exist or has already been replaced (a deleted-and-recreated object reusing
+// the same name gets a new UID). namespace is the ServiceAccount's namespace; Pod and Secret
+// bindings are always looked up within it, since a bound token is only meaningful for an object
+// that can be said to "belong" to the same namespace as the ServiceAccount minting it.
+func (r *TokenREST) validateBoundObjectRef(ctx context.Context, ref *authenticationapi.BoundObjectReference, namespace string) (*api.ObjectReference, error) {
+	var getter rest.Getter
+	var resource string
+	switch ref.Kind {
+	case "Pod":
+		getter, resource = r.pods, "pods"
+	case "Secret":
+		getter, resource = r.secrets, "secrets"
+	case "Node":
+		getter, resource = r.nodes, "nodes"
+	default:
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("unsupported boundObjectRef kind %q", ref.Kind))
+	}
+	if getter == nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("boundObjectRef kind %q is not supported by this apiserver", ref.Kind))
+	}
+
+	obj, err := getter.Get(ctx, ref.Name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var uid types.UID
+	switch o := obj.(type) {
+	case *api.Pod:
+		uid = o.UID
+	case *api.Secret:
+		uid = o.UID
+	case *api.Node:
+		uid = o.UID
+	default:
+		return nil, fmt.Errorf("unexpected type %T for boundObjectRef kind %q", obj, ref.Kind)
+	}
+	if ref.UID != "" && ref.UID != uid {
+		return nil, apierrors.NewConflict(api.Resource(resource), ref.Name, fmt.Errorf("the UID in boundObjectRef (%s) does not match the current UID of %s %q (%s)", ref.UID, ref.Kind, ref.Name, uid))
+	}
+
+	objRef := &api.ObjectReference{Kind: ref.Kind, APIVersion: ref.APIVersion, Name: ref.Name, UID: uid}
+	if ref.Kind != "Node" {
+		objRef.Namespace = namespace
+	}
+	return objRef, nil
+}
+
+// IsRevoked reports whether jti has been revoked. The token authenticator calls this on every
+// request bearing a bound service-account token; it returns false (never revoked) when
+// revocation is disabled.
+func (r *TokenREST) IsRevoked(jti string) bool {
+	return r.revocations != nil && r.revocations.IsRevoked(jti)
+}
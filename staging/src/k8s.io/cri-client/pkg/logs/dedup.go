@@ -18,30 +18,97 @@ package logs
 
 import (
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"k8s.io/component-base/metrics"
+)
+
+// DedupOptions configures the coalescing window used by dedupWriteEventsWatcher.
+type DedupOptions struct {
+	// MinInterval is the coalescing window used for a quiet container, and the floor the
+	// window decays back down to once bursts subside.
+	MinInterval time.Duration
+	// MaxInterval is the ceiling the coalescing window grows toward while a container is
+	// writing faster than the current window.
+	MaxInterval time.Duration
+	// BurstThreshold is the number of consecutive writes observed faster (or slower) than the
+	// current window required before the window doubles toward MaxInterval (or halves back
+	// toward MinInterval).
+	BurstThreshold int
+}
+
+// DefaultDedupOptions returns the DedupOptions used when logs.ReadLogs is not given an explicit
+// override, preserving the previous fixed 150ms behavior as the starting point.
+func DefaultDedupOptions() DedupOptions {
+	return DedupOptions{
+		MinInterval:    150 * time.Millisecond,
+		MaxInterval:    2 * time.Second,
+		BurstThreshold: 3,
+	}
+}
+
+var (
+	dedupEventsCoalescedTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name:           "kubelet_log_tail_events_coalesced_total",
+		Help:           "Number of fsnotify write events dropped by the log-tailing deduplicator, by container.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"container_id"})
+	dedupEventsDeliveredTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name:           "kubelet_log_tail_events_delivered_total",
+		Help:           "Number of fsnotify write events delivered by the log-tailing deduplicator, by container.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"container_id"})
+	dedupReopenTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name:           "kubelet_log_tail_reopen_total",
+		Help:           "Number of times the log-tailing watcher reopened a rotated log file, by container.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"container_id"})
+
+	registerMetricsOnce sync.Once
 )
 
-const waitDuration = 150 * time.Millisecond
+// RegisterMetrics registers the log-tailing deduplicator's metrics with registerer. Kubelet calls
+// this once, with its component-base legacyregistry, during startup; it is a no-op on subsequent
+// calls so tests and multiple callers can't trip a duplicate-registration panic.
+func RegisterMetrics(registerer metrics.KubeRegistry) {
+	registerMetricsOnce.Do(func() {
+		registerer.MustRegister(dedupEventsCoalescedTotal, dedupEventsDeliveredTotal, dedupReopenTotal)
+	})
+}
 
 // dedupWriteEventsWatcher reduce write events from fsnotify.Watcher to reduce calling function isContainerRunning.
 // When container output logs quickly, and user run command `kubectl logs CONTAINER_ID -f`,
 // then there will be too much function call of `isContainerRunning`.
 // This will consume CPU time of kubelet and containerd.
+//
+// The coalescing window starts at opts.MinInterval and adapts: it doubles toward
+// opts.MaxInterval after opts.BurstThreshold consecutive writes land faster than the current
+// window (a hot container), and halves back toward opts.MinInterval after opts.BurstThreshold
+// consecutive writes land slower than it (the container has quieted down).
 type dedupWriteEventsWatcher struct {
 	*fsnotify.Watcher
 	logFileName string
+	containerID string
+	opts        DedupOptions
 
 	// Events sends fsnotify events.
 	Events chan fsnotify.Event
 	done   chan struct{}
 }
 
-func newDedupWriteEventsWatcher(logfileName string, w *fsnotify.Watcher) *dedupWriteEventsWatcher {
+// newDedupWriteEventsWatcher is called from logs.ReadLogs (pkg/kubelet/kuberuntime/logs, outside
+// this package) once per tailed container, with containerID labeling this container's series of
+// the kubelet_log_tail_* metrics and opts the coalescing window ReadLogs was configured with
+// (DefaultDedupOptions() if the caller didn't override it).
+func newDedupWriteEventsWatcher(logfileName, containerID string, opts DedupOptions, w *fsnotify.Watcher) *dedupWriteEventsWatcher {
 	return &dedupWriteEventsWatcher{
 		Watcher:     w,
 		logFileName: logfileName,
+		containerID: containerID,
+		opts:        opts,
 		Events:      make(chan fsnotify.Event, 4),
 		done:        make(chan struct{}),
 	}
@@ -55,6 +122,14 @@ func (de *dedupWriteEventsWatcher) Close() error {
 	// clean all events in channel
 	for range de.Events {
 	}
+
+	// Drop this container's series now that it has stopped being tailed, so the label set is
+	// bounded by the number of containers currently being tailed rather than growing for the
+	// life of the kubelet process.
+	dedupEventsCoalescedTotal.DeleteLabelValues(de.containerID)
+	dedupEventsDeliveredTotal.DeleteLabelValues(de.containerID)
+	dedupReopenTotal.DeleteLabelValues(de.containerID)
+
 	return err
 }
 
@@ -62,6 +137,8 @@ func (de *dedupWriteEventsWatcher) Close() error {
 func (de *dedupWriteEventsWatcher) dedupLoop() {
 	defer close(de.done)
 
+	interval := de.opts.MinInterval
+	var fastStreak, slowStreak int
 	var lastAdd time.Time
 	for e := range de.Watcher.Events {
 		if filepath.Base(e.Name) != de.logFileName {
@@ -73,11 +150,22 @@ func (de *dedupWriteEventsWatcher) dedupLoop() {
 			// If there is one Write event in the channel, we can discard this one.
 			// If there is one Create event in the channel, we will reopen the log file, and read from the
 			// start. It's OK to discard the new Write event.
-			if len(de.Events) > 0 ||
-				time.Since(lastAdd) < waitDuration {
+			if len(de.Events) > 0 || time.Since(lastAdd) < interval {
+				dedupEventsCoalescedTotal.WithLabelValues(de.containerID).Inc()
+				slowStreak = 0
+				if fastStreak++; fastStreak >= de.opts.BurstThreshold {
+					interval = growInterval(interval, de.opts.MaxInterval)
+					fastStreak = 0
+				}
 				continue
 			}
+			fastStreak = 0
+			if slowStreak++; slowStreak >= de.opts.BurstThreshold {
+				interval = shrinkInterval(interval, de.opts.MinInterval)
+				slowStreak = 0
+			}
 			lastAdd = time.Now()
+			dedupEventsDeliveredTotal.WithLabelValues(de.containerID).Inc()
 		case fsnotify.Create:
 			// Always add a write event before create event. In case lost some log lines.
 			de.Events <- fsnotify.Event{
@@ -85,8 +173,25 @@ func (de *dedupWriteEventsWatcher) dedupLoop() {
 				Op:   fsnotify.Write,
 			}
 			lastAdd = time.Now()
+			dedupReopenTotal.WithLabelValues(de.containerID).Inc()
 		default:
 		}
 		de.Events <- e
 	}
 }
+
+// growInterval doubles interval, capped at max.
+func growInterval(interval, max time.Duration) time.Duration {
+	if next := interval * 2; next < max {
+		return next
+	}
+	return max
+}
+
+// shrinkInterval halves interval, floored at min.
+func shrinkInterval(interval, min time.Duration) time.Duration {
+	if next := interval / 2; next > min {
+		return next
+	}
+	return min
+}
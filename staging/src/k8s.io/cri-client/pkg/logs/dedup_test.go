@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGrowInterval(t *testing.T) {
+	max := 2 * time.Second
+	cases := []struct {
+		interval time.Duration
+		want     time.Duration
+	}{
+		{150 * time.Millisecond, 300 * time.Millisecond},
+		{time.Second, 2 * time.Second},
+		{1500 * time.Millisecond, max}, // doubling would overshoot max, so it's capped
+		{max, max},
+	}
+	for _, c := range cases {
+		if got := growInterval(c.interval, max); got != c.want {
+			t.Errorf("growInterval(%v, %v) = %v, want %v", c.interval, max, got, c.want)
+		}
+	}
+}
+
+func TestShrinkInterval(t *testing.T) {
+	min := 150 * time.Millisecond
+	cases := []struct {
+		interval time.Duration
+		want     time.Duration
+	}{
+		{2 * time.Second, time.Second},
+		{300 * time.Millisecond, 150 * time.Millisecond},
+		{200 * time.Millisecond, min}, // halving would undershoot min, so it's floored
+		{min, min},
+	}
+	for _, c := range cases {
+		if got := shrinkInterval(c.interval, min); got != c.want {
+			t.Errorf("shrinkInterval(%v, %v) = %v, want %v", c.interval, min, got, c.want)
+		}
+	}
+}
+
+func TestDefaultDedupOptions(t *testing.T) {
+	opts := DefaultDedupOptions()
+	if opts.MinInterval != 150*time.Millisecond {
+		t.Errorf("MinInterval = %v, want 150ms", opts.MinInterval)
+	}
+	if opts.MaxInterval <= opts.MinInterval {
+		t.Errorf("MaxInterval (%v) must be greater than MinInterval (%v)", opts.MaxInterval, opts.MinInterval)
+	}
+	if opts.BurstThreshold <= 0 {
+		t.Errorf("BurstThreshold = %d, want > 0", opts.BurstThreshold)
+	}
+}